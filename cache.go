@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cacheConfig controls where the on-disk snapshot lives and how old it can
+// get before /healthz starts reporting trouble. Both are read from the
+// environment so the binary stays dependency-free:
+//
+//	CURRENCY_API_CACHE_FILE=/var/lib/currency-api/rates.gob (default "exchange-rates.gob")
+//	CURRENCY_API_STALE_THRESHOLD=36h (default 36h, parsed with time.ParseDuration)
+type cacheConfig struct {
+	FilePath       string
+	StaleThreshold time.Duration
+}
+
+func loadCacheConfig() cacheConfig {
+	cfg := cacheConfig{FilePath: "exchange-rates.gob", StaleThreshold: 36 * time.Hour}
+	if v := os.Getenv("CURRENCY_API_CACHE_FILE"); v != "" {
+		cfg.FilePath = v
+	}
+	if v := os.Getenv("CURRENCY_API_STALE_THRESHOLD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.StaleThreshold = d
+		}
+	}
+	return cfg
+}
+
+var cacheCfg = loadCacheConfig()
+
+// exchangeRatesMu guards exchangeRates and lastFetchAt, which are written by
+// the periodic updater and read by every HTTP request.
+var exchangeRatesMu sync.RWMutex
+var lastFetchAt time.Time
+
+// exchangeRatesSnapshot is the gob-encoded shape persisted to FilePath.
+type exchangeRatesSnapshot struct {
+	ExchangeRates map[string][]exchange
+	LastFetchAt   time.Time
+}
+
+// loadExchangeRatesSnapshot warm-starts exchangeRates from the on-disk
+// snapshot, if any. A missing or corrupt file is not fatal: the periodic
+// updater will populate the cache from a Provider instead.
+func loadExchangeRatesSnapshot() {
+	f, err := os.Open(cacheCfg.FilePath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var snap exchangeRatesSnapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil {
+		fmt.Printf("Unable to decode exchange rate snapshot: %v\n", err)
+		return
+	}
+
+	exchangeRatesMu.Lock()
+	exchangeRates = snap.ExchangeRates
+	lastFetchAt = snap.LastFetchAt
+	refreshAvailableDates()
+	exchangeRatesMu.Unlock()
+}
+
+// saveExchangeRatesSnapshot atomically rewrites the on-disk snapshot: it
+// writes to a temp file in the same directory and renames it into place, so
+// a crash mid-write never leaves a truncated snapshot behind.
+func saveExchangeRatesSnapshot() error {
+	exchangeRatesMu.RLock()
+	snap := exchangeRatesSnapshot{ExchangeRates: exchangeRates, LastFetchAt: lastFetchAt}
+	exchangeRatesMu.RUnlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(cacheCfg.FilePath), ".exchange-rates-*.gob.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(snap); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), cacheCfg.FilePath)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExchangeRatesSnapshotRoundTrip(t *testing.T) {
+	origCfg := cacheCfg
+	t.Cleanup(func() { cacheCfg = origCfg })
+	cacheCfg = cacheConfig{FilePath: filepath.Join(t.TempDir(), "rates.gob"), StaleThreshold: origCfg.StaleThreshold}
+
+	want := map[string][]exchange{
+		"2026-07-24": {{Currency: "USD", Rate: 1.10}},
+		"2026-07-27": {{Currency: "USD", Rate: 1.11}, {Currency: "GBP", Rate: 0.85}},
+	}
+	fetchedAt := time.Date(2026, 7, 27, 12, 0, 0, 0, time.UTC)
+
+	seedExchangeRates(t, want)
+	exchangeRatesMu.Lock()
+	lastFetchAt = fetchedAt
+	exchangeRatesMu.Unlock()
+
+	if err := saveExchangeRatesSnapshot(); err != nil {
+		t.Fatalf("saveExchangeRatesSnapshot returned error: %v", err)
+	}
+
+	// Clear in-memory state to prove the load actually repopulates it.
+	seedExchangeRates(t, map[string][]exchange{})
+	exchangeRatesMu.Lock()
+	lastFetchAt = time.Time{}
+	exchangeRatesMu.Unlock()
+
+	loadExchangeRatesSnapshot()
+
+	exchangeRatesMu.RLock()
+	defer exchangeRatesMu.RUnlock()
+	if len(exchangeRates) != len(want) {
+		t.Fatalf("exchangeRates = %v, want %v", exchangeRates, want)
+	}
+	for date, rates := range want {
+		got, ok := exchangeRates[date]
+		if !ok {
+			t.Errorf("missing date %q after reload", date)
+			continue
+		}
+		if len(got) != len(rates) {
+			t.Errorf("exchangeRates[%q] = %v, want %v", date, got, rates)
+		}
+	}
+	if !lastFetchAt.Equal(fetchedAt) {
+		t.Errorf("lastFetchAt = %v, want %v", lastFetchAt, fetchedAt)
+	}
+}
+
+func TestLoadExchangeRatesSnapshotMissingFileIsNotFatal(t *testing.T) {
+	origCfg := cacheCfg
+	t.Cleanup(func() { cacheCfg = origCfg })
+	cacheCfg = cacheConfig{FilePath: filepath.Join(t.TempDir(), "does-not-exist.gob"), StaleThreshold: origCfg.StaleThreshold}
+
+	seedExchangeRates(t, map[string][]exchange{"2026-07-27": {{Currency: "USD", Rate: 1.11}}})
+
+	// A missing snapshot file must leave the already-seeded cache untouched.
+	loadExchangeRatesSnapshot()
+
+	exchangeRatesMu.RLock()
+	defer exchangeRatesMu.RUnlock()
+	if len(exchangeRates) != 1 {
+		t.Errorf("exchangeRates = %v, want the pre-seeded single entry untouched", exchangeRates)
+	}
+}
@@ -1,14 +1,13 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
+	"context"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"regexp"
 	"runtime"
+	"strconv"
 	"time"
 )
 
@@ -63,26 +62,20 @@ var desiredCurrencies = map[string]struct{}{
 	"ZAR": struct{}{},
 }
 
-// last 90 days are available at http://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist-90d.xml
 var eurHistURL = "http://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
-var exchangeRates = map[string][]exchange{}
 
-func downloadExchangeRates() (io.Reader, error) {
-	resp, err := http.Get(eurHistURL)
-	if err != nil {
-		return nil, err
-	}
+// eurHist90dURL is a lighter feed covering only the last 90 days; /series
+// uses it to freshen a short requested range without waiting on the next
+// hourly full-history refresh.
+var eurHist90dURL = "http://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist-90d.xml"
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP request returned %v", resp.Status)
-	}
+var exchangeRates = map[string][]exchange{}
 
-	return resp.Body, nil
-}
+var providerChain = newProviderChain(loadProviderConfig())
 
-func filterExchangeRates(c cube) []exchange {
+func filterExchangeRates(exs []exchange) []exchange {
 	var rates []exchange
-	for _, ex := range c.Exchanges {
+	for _, ex := range exs {
 		if _, ok := desiredCurrencies[ex.Currency]; ok {
 			rates = append(rates, ex)
 		}
@@ -90,29 +83,25 @@ func filterExchangeRates(c cube) []exchange {
 	return rates
 }
 
-func updateExchangeRates(data io.Reader) error {
-	var e envelop
-	decoder := xml.NewDecoder(data)
-	if err := decoder.Decode(&e); err != nil {
-		return err
+func updateExchangeRatesCache() {
+	days, err := providerChain.Fetch(context.Background())
+	if err != nil {
+		fmt.Printf("Unable to fetch exchange rates: %v\n", err)
+		return
 	}
 
-	for _, c := range e.Cubes {
-		if _, ok := exchangeRates[c.Date]; !ok {
-			exchangeRates[c.Date] = filterExchangeRates(c)
+	exchangeRatesMu.Lock()
+	for _, d := range days {
+		if _, ok := exchangeRates[d.Date]; !ok {
+			exchangeRates[d.Date] = d.Rates
 		}
 	}
+	lastFetchAt = time.Now()
+	refreshAvailableDates()
+	exchangeRatesMu.Unlock()
 
-	return nil
-}
-
-func updateExchangeRatesCache() {
-	if reader, err := downloadExchangeRates(); err != nil {
-		fmt.Printf("Unable to download exchange rates. Is the URL correct?")
-	} else {
-		if err := updateExchangeRates(reader); err != nil {
-			fmt.Printf("Failed to update exchange rates: %v", err)
-		}
+	if err := saveExchangeRatesSnapshot(); err != nil {
+		fmt.Printf("Unable to persist exchange rate snapshot: %v\n", err)
 	}
 }
 
@@ -124,12 +113,27 @@ func exchangeRatesByCurrency(rates []exchange) map[string]float32 {
 	return mappedByCurrency
 }
 
-// accept strings like /1986-09-03 and /1986-09-03/USD
-var routingRegexp = regexp.MustCompile(`/(\d{4}-\d{2}-\d{2})/?([A-Za-z]{3})?`)
+// accept strings like /1986-09-03, /1986-09-03/USD and /1986-09-03/USD/GBP
+var routingRegexp = regexp.MustCompile(`/(\d{4}-\d{2}-\d{2})/?([A-Za-z]{3})?/?([A-Za-z]{3})?`)
 
 func newCurrencyExchangeServer() http.Handler {
 	r := http.NewServeMux()
 
+	r.HandleFunc("/series/", seriesHandler)
+
+	r.HandleFunc("/healthz", func(w http.ResponseWriter, req *http.Request) {
+		exchangeRatesMu.RLock()
+		fetchedAt := lastFetchAt
+		exchangeRatesMu.RUnlock()
+
+		if fetchedAt.IsZero() || time.Since(fetchedAt) > cacheCfg.StaleThreshold {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "stale: last fetch %s\n", fetchedAt)
+			return
+		}
+		fmt.Fprintf(w, "ok: last fetch %s\n", fetchedAt)
+	})
+
 	r.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
 		if !routingRegexp.MatchString(req.URL.Path) {
 			w.WriteHeader(http.StatusBadRequest)
@@ -138,27 +142,58 @@ func newCurrencyExchangeServer() http.Handler {
 
 		parts := routingRegexp.FindAllStringSubmatch(req.URL.Path, -1)[0]
 		requestedDate := parts[1]
-		requestedCurrency := parts[2]
+		fromCurrency := parts[2]
+		toCurrency := parts[3]
 
-		enc := json.NewEncoder(w)
-		if _, ok := exchangeRates[requestedDate]; !ok {
+		q := req.URL.Query()
+		maxDays := dateFallbackCfg.MaxDays
+		if q.Get("strict") == "true" {
+			maxDays = 0
+		}
+
+		effectiveDate, ok := resolveDate(requestedDate, q.Get("direction"), maxDays)
+		if !ok {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
 
-		var exs = exchangeRates[requestedDate]
-		if requestedCurrency == "" {
-			enc.Encode(exchangeRatesByCurrency(exs))
-		} else {
-			for _, rate := range exs {
-				if rate.Currency == parts[2] {
-					enc.Encode(rate)
-					return
-				}
-			}
+		exchangeRatesMu.RLock()
+		exs := exchangeRates[effectiveDate]
+		exchangeRatesMu.RUnlock()
+
+		if fromCurrency == "" {
+			encodeWithEffectiveDate(w, exchangeRatesByCurrency(exs), requestedDate, effectiveDate)
+			return
+		}
+
+		normalized, err := Normalize(exs, fromCurrency)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 
+		if toCurrency == "" {
+			encodeWithEffectiveDate(w, normalized, requestedDate, effectiveDate)
+			return
+		}
+
+		rate, ok := normalized[toCurrency]
+		if !ok {
 			w.WriteHeader(http.StatusNotFound)
+			return
 		}
+
+		if amountParam := q.Get("amount"); amountParam != "" {
+			amount, err := strconv.ParseFloat(amountParam, 32)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			encodeWithEffectiveDate(w, exchange{Currency: toCurrency, Rate: float32(amount) * rate}, requestedDate, effectiveDate)
+			return
+		}
+
+		encodeWithEffectiveDate(w, exchange{Currency: toCurrency, Rate: rate}, requestedDate, effectiveDate)
 	})
 
 	return http.Handler(r)
@@ -173,6 +208,7 @@ func updateExchangeRatesPeriodically() {
 }
 
 func init() {
+	loadExchangeRatesSnapshot()
 	updateExchangeRatesCache()
 }
 
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// availableDates is a sorted (ascending) index of exchangeRates' keys,
+// maintained alongside the map so date resolution can bisect instead of
+// scanning. Guarded by exchangeRatesMu, same as exchangeRates.
+var availableDates []string
+
+// refreshAvailableDates rebuilds availableDates from exchangeRates. Callers
+// must already hold exchangeRatesMu for writing.
+func refreshAvailableDates() {
+	availableDates = make([]string, 0, len(exchangeRates))
+	for d := range exchangeRates {
+		availableDates = append(availableDates, d)
+	}
+	sort.Strings(availableDates)
+}
+
+// dateFallbackConfig controls how many calendar days the resolver is
+// willing to walk to find a neighboring date with data. Read from the
+// environment:
+//
+//	CURRENCY_API_DATE_FALLBACK_DAYS=7 (default 7)
+type dateFallbackConfig struct {
+	MaxDays int
+}
+
+func loadDateFallbackConfig() dateFallbackConfig {
+	cfg := dateFallbackConfig{MaxDays: 7}
+	if v := os.Getenv("CURRENCY_API_DATE_FALLBACK_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.MaxDays = n
+		}
+	}
+	return cfg
+}
+
+var dateFallbackCfg = loadDateFallbackConfig()
+
+// resolveDate returns the date that should be served for requestedDate: the
+// exact date if it has rates, otherwise the nearest date within maxDays
+// (earlier by default, later when direction is "forward"). ok is false if
+// nothing in range has data.
+func resolveDate(requestedDate, direction string, maxDays int) (date string, ok bool) {
+	exchangeRatesMu.RLock()
+	defer exchangeRatesMu.RUnlock()
+
+	if _, ok := exchangeRates[requestedDate]; ok {
+		return requestedDate, true
+	}
+
+	i := sort.SearchStrings(availableDates, requestedDate)
+
+	if direction == "forward" {
+		if i >= len(availableDates) {
+			return "", false
+		}
+		candidate := availableDates[i]
+		if daysBetween(requestedDate, candidate) <= maxDays {
+			return candidate, true
+		}
+		return "", false
+	}
+
+	if i == 0 {
+		return "", false
+	}
+	candidate := availableDates[i-1]
+	if daysBetween(candidate, requestedDate) <= maxDays {
+		return candidate, true
+	}
+	return "", false
+}
+
+// effectiveDateResponse wraps a rates payload that was resolved from a date
+// other than the one requested, so the effective_date field can never be
+// mistaken for a currency code in a bare rates map.
+type effectiveDateResponse struct {
+	EffectiveDate string      `json:"effective_date"`
+	Rates         interface{} `json:"rates"`
+}
+
+// encodeWithEffectiveDate writes payload as JSON, wrapping it with an
+// "effective_date" field and setting the X-Effective-Date header when the
+// resolver had to fall back away from requestedDate.
+func encodeWithEffectiveDate(w http.ResponseWriter, payload interface{}, requestedDate, effectiveDate string) {
+	if effectiveDate == requestedDate {
+		json.NewEncoder(w).Encode(payload)
+		return
+	}
+
+	w.Header().Set("X-Effective-Date", effectiveDate)
+	json.NewEncoder(w).Encode(effectiveDateResponse{EffectiveDate: effectiveDate, Rates: payload})
+}
+
+// daysBetween returns the number of calendar days between earlier and
+// later, or a value greater than any sane maxDays if either fails to parse.
+func daysBetween(earlier, later string) int {
+	t1, err1 := time.Parse("2006-01-02", earlier)
+	t2, err2 := time.Parse("2006-01-02", later)
+	if err1 != nil || err2 != nil {
+		return 1 << 30
+	}
+	diff := t2.Sub(t1)
+	if diff < 0 {
+		return 1 << 30
+	}
+	return int(diff / (24 * time.Hour))
+}
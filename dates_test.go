@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestResolveDate(t *testing.T) {
+	seedExchangeRates(t, map[string][]exchange{
+		"2026-07-17": {{Currency: "USD", Rate: 1.10}},
+		"2026-07-24": {{Currency: "USD", Rate: 1.11}},
+	})
+
+	tests := []struct {
+		name          string
+		requestedDate string
+		direction     string
+		maxDays       int
+		wantDate      string
+		wantOK        bool
+	}{
+		{"exact match ignores maxDays", "2026-07-24", "", 0, "2026-07-24", true},
+		{"weekend walks back to the prior Friday", "2026-07-25", "", 7, "2026-07-24", true},
+		{"forward direction walks to the next available date", "2026-07-20", "forward", 7, "2026-07-24", true},
+		{"strict (maxDays 0) rejects a missing date", "2026-07-25", "", 0, "", false},
+		{"out of range backward walk fails", "2026-07-19", "", 1, "", false},
+		{"nothing available before the earliest date", "2026-07-01", "", 7, "", false},
+		{"nothing available after the latest date", "2026-08-01", "forward", 7, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDate, gotOK := resolveDate(tt.requestedDate, tt.direction, tt.maxDays)
+			if gotOK != tt.wantOK {
+				t.Fatalf("resolveDate(%q, %q, %d) ok = %v, want %v", tt.requestedDate, tt.direction, tt.maxDays, gotOK, tt.wantOK)
+			}
+			if gotOK && gotDate != tt.wantDate {
+				t.Errorf("resolveDate(%q, %q, %d) = %q, want %q", tt.requestedDate, tt.direction, tt.maxDays, gotDate, tt.wantDate)
+			}
+		})
+	}
+}
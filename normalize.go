@@ -0,0 +1,29 @@
+package main
+
+import "fmt"
+
+// Normalize rebases rates (quoted against EUR, as stored in exchangeRates) so
+// that base reads 1.0 and every other currency is rescaled by 1/rate(base).
+// EUR itself is added as a synthetic 1.0 entry first, so it can be requested
+// as a base or as a target even though the underlying data never reports it
+// directly.
+func Normalize(rates []exchange, base string) (map[string]float32, error) {
+	withEUR := append([]exchange{{Currency: "EUR", Rate: 1}}, rates...)
+
+	var baseRate float32
+	for _, ex := range withEUR {
+		if ex.Currency == base {
+			baseRate = ex.Rate
+			break
+		}
+	}
+	if baseRate == 0 {
+		return nil, fmt.Errorf("currency %s not found", base)
+	}
+
+	normalized := make(map[string]float32, len(withEUR))
+	for _, ex := range withEUR {
+		normalized[ex.Currency] = ex.Rate / baseRate
+	}
+	return normalized, nil
+}
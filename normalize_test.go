@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	rates := []exchange{
+		{Currency: "USD", Rate: 1.10},
+		{Currency: "GBP", Rate: 0.85},
+	}
+
+	t.Run("base EUR returns rates unchanged plus the synthetic EUR entry", func(t *testing.T) {
+		got, err := Normalize(rates, "EUR")
+		if err != nil {
+			t.Fatalf("Normalize returned error: %v", err)
+		}
+		want := map[string]float32{"EUR": 1, "USD": 1.10, "GBP": 0.85}
+		for currency, rate := range want {
+			if got[currency] != rate {
+				t.Errorf("got[%q] = %v, want %v", currency, got[currency], rate)
+			}
+		}
+	})
+
+	t.Run("base USD rescales every currency by 1/rate(USD)", func(t *testing.T) {
+		got, err := Normalize(rates, "USD")
+		if err != nil {
+			t.Fatalf("Normalize returned error: %v", err)
+		}
+		if got["USD"] != 1 {
+			t.Errorf("got[USD] = %v, want 1", got["USD"])
+		}
+		if got["EUR"] != float32(1)/1.10 {
+			t.Errorf("got[EUR] = %v, want %v", got["EUR"], float32(1)/1.10)
+		}
+		if got["GBP"] != float32(0.85)/1.10 {
+			t.Errorf("got[GBP] = %v, want %v", got["GBP"], float32(0.85)/1.10)
+		}
+	})
+
+	t.Run("unknown base returns an error", func(t *testing.T) {
+		if _, err := Normalize(rates, "ZZZ"); err == nil {
+			t.Error("Normalize returned nil error for an unknown base currency")
+		}
+	})
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DayRates is a single day's exchange rates as reported by a Provider, all
+// quoted against Base (e.g. "EUR" means each Rate is units of Currency per
+// 1 EUR).
+type DayRates struct {
+	Date  string
+	Base  string
+	Rates []exchange
+}
+
+// Provider fetches historical exchange rates from a single upstream source.
+type Provider interface {
+	// Name identifies the provider for logging and config selection.
+	Name() string
+	// Fetch retrieves the full set of historical rates available from this source.
+	Fetch(ctx context.Context) ([]DayRates, error)
+}
+
+// ProviderConfig selects the primary rate source and whether to fall back to
+// the others when it fails, mirroring ezbookkeeping's data_source setting.
+// It is read from the environment so the binary stays dependency-free:
+//
+//	CURRENCY_API_PRIMARY_PROVIDER=ecb|imf|boc|rba|cnb|nbp (default "ecb")
+//	CURRENCY_API_ENABLE_FALLBACK=false to disable fallback (default true)
+type ProviderConfig struct {
+	Primary        string
+	EnableFallback bool
+}
+
+func loadProviderConfig() ProviderConfig {
+	cfg := ProviderConfig{Primary: "ecb", EnableFallback: true}
+	if v := os.Getenv("CURRENCY_API_PRIMARY_PROVIDER"); v != "" {
+		cfg.Primary = strings.ToLower(v)
+	}
+	if v := os.Getenv("CURRENCY_API_ENABLE_FALLBACK"); v == "false" {
+		cfg.EnableFallback = false
+	}
+	return cfg
+}
+
+// allProviders returns every known provider, keyed by Name().
+func allProviders() map[string]Provider {
+	providers := []Provider{
+		newECBProvider(eurHistURL),
+		newIMFProvider(),
+		newBOCProvider(),
+		newRBAProvider(),
+		newCNBProvider(),
+		newNBPProvider(),
+	}
+	byName := make(map[string]Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return byName
+}
+
+// ProviderChain queries a primary Provider and, on error or empty results,
+// merges in whatever the remaining providers can supply, normalizing
+// everything to a common EUR base along the way.
+type ProviderChain struct {
+	primary        Provider
+	fallbacks      []Provider
+	enableFallback bool
+}
+
+func newProviderChain(cfg ProviderConfig) *ProviderChain {
+	byName := allProviders()
+
+	primary, ok := byName[cfg.Primary]
+	if !ok {
+		primary = byName["ecb"]
+	}
+
+	var fallbacks []Provider
+	for name, p := range byName {
+		if name == primary.Name() {
+			continue
+		}
+		fallbacks = append(fallbacks, p)
+	}
+
+	return &ProviderChain{primary: primary, fallbacks: fallbacks, enableFallback: cfg.EnableFallback}
+}
+
+func (c *ProviderChain) Fetch(ctx context.Context) ([]DayRates, error) {
+	rawDays, primaryErr := c.primary.Fetch(ctx)
+	days := rebaseAndFilterDays(rawDays)
+	if primaryErr == nil && len(days) > 0 {
+		return days, nil
+	}
+
+	if !c.enableFallback {
+		if primaryErr != nil {
+			return nil, fmt.Errorf("%s: %w", c.primary.Name(), primaryErr)
+		}
+		return nil, fmt.Errorf("%s: returned no usable rates", c.primary.Name())
+	}
+
+	for _, p := range c.fallbacks {
+		fbDays, err := p.Fetch(ctx)
+		if err != nil || len(fbDays) == 0 {
+			continue
+		}
+		days = mergeDayRates(days, rebaseAndFilterDays(fbDays))
+	}
+
+	if len(days) == 0 {
+		if primaryErr != nil {
+			return nil, fmt.Errorf("%s: %w", c.primary.Name(), primaryErr)
+		}
+		return nil, fmt.Errorf("no provider returned any rates")
+	}
+	return days, nil
+}
+
+// rebaseToEUR converts a DayRates quoted against an arbitrary base currency
+// into one quoted against EUR, using that day's own EUR cross rate. It
+// fails if the day doesn't carry an EUR rate to pivot through.
+func rebaseToEUR(day DayRates) (DayRates, error) {
+	if day.Base == "EUR" {
+		return day, nil
+	}
+
+	var eurRate float32
+	for _, ex := range day.Rates {
+		if ex.Currency == "EUR" {
+			eurRate = ex.Rate
+			break
+		}
+	}
+	if eurRate == 0 {
+		return DayRates{}, fmt.Errorf("%s: no EUR cross rate on %s", day.Base, day.Date)
+	}
+
+	rebased := make([]exchange, 0, len(day.Rates))
+	for _, ex := range day.Rates {
+		if ex.Currency == "EUR" {
+			continue
+		}
+		rebased = append(rebased, exchange{Currency: ex.Currency, Rate: ex.Rate / eurRate})
+	}
+	rebased = append(rebased, exchange{Currency: day.Base, Rate: 1 / eurRate})
+
+	return DayRates{Date: day.Date, Base: "EUR", Rates: rebased}, nil
+}
+
+// rebaseAndFilterDays converts every day to EUR and restricts it to
+// desiredCurrencies. Rebasing has to run before filtering: a non-EUR
+// provider's own EUR cross rate is the pivot rebaseToEUR needs, and
+// desiredCurrencies deliberately excludes "EUR" (see currency-api.go), so
+// filtering first would strip that pivot before it could ever be used.
+func rebaseAndFilterDays(days []DayRates) []DayRates {
+	out := make([]DayRates, 0, len(days))
+	for _, d := range days {
+		rebased, err := rebaseToEUR(d)
+		if err != nil {
+			continue
+		}
+		rebased.Rates = filterExchangeRates(rebased.Rates)
+		out = append(out, rebased)
+	}
+	return out
+}
+
+// mergeDayRates folds secondary into primary, only filling in currencies
+// missing for a given date rather than overwriting anything primary already
+// reported. Both slices are expected to already be EUR-based and filtered
+// (see rebaseAndFilterDays).
+func mergeDayRates(primary, secondary []DayRates) []DayRates {
+	byDate := make(map[string]map[string]float32)
+	var order []string
+
+	for _, d := range primary {
+		if _, ok := byDate[d.Date]; !ok {
+			byDate[d.Date] = make(map[string]float32)
+			order = append(order, d.Date)
+		}
+		for _, ex := range d.Rates {
+			byDate[d.Date][ex.Currency] = ex.Rate
+		}
+	}
+
+	for _, d := range secondary {
+		if _, ok := byDate[d.Date]; !ok {
+			byDate[d.Date] = make(map[string]float32)
+			order = append(order, d.Date)
+		}
+		for _, ex := range d.Rates {
+			if _, exists := byDate[d.Date][ex.Currency]; !exists {
+				byDate[d.Date][ex.Currency] = ex.Rate
+			}
+		}
+	}
+
+	merged := make([]DayRates, 0, len(order))
+	for _, date := range order {
+		rates := make([]exchange, 0, len(byDate[date]))
+		for currency, rate := range byDate[date] {
+			rates = append(rates, exchange{Currency: currency, Rate: rate})
+		}
+		merged = append(merged, DayRates{Date: date, Base: "EUR", Rates: rates})
+	}
+	return merged
+}
+
+// ecbProvider is the original eurofxref-hist.xml source.
+type ecbProvider struct {
+	url string
+}
+
+func newECBProvider(url string) *ecbProvider {
+	return &ecbProvider{url: url}
+}
+
+func (p *ecbProvider) Name() string { return "ecb" }
+
+func (p *ecbProvider) Fetch(ctx context.Context) ([]DayRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ecb: HTTP request returned %v", resp.Status)
+	}
+
+	var e envelop
+	if err := xml.NewDecoder(resp.Body).Decode(&e); err != nil {
+		return nil, err
+	}
+
+	days := make([]DayRates, 0, len(e.Cubes))
+	for _, c := range e.Cubes {
+		days = append(days, DayRates{Date: c.Date, Base: "EUR", Rates: c.Exchanges})
+	}
+	return days, nil
+}
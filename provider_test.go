@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRebaseToEUR(t *testing.T) {
+	t.Run("already EUR-based day is returned unchanged", func(t *testing.T) {
+		day := DayRates{Date: "2026-07-27", Base: "EUR", Rates: []exchange{{Currency: "USD", Rate: 1.1}}}
+		got, err := rebaseToEUR(day)
+		if err != nil {
+			t.Fatalf("rebaseToEUR returned error: %v", err)
+		}
+		if !reflect.DeepEqual(got, day) {
+			t.Errorf("got %+v, want %+v unchanged", got, day)
+		}
+	})
+
+	t.Run("pivots through the day's own EUR cross rate", func(t *testing.T) {
+		day := DayRates{Date: "2026-07-27", Base: "USD", Rates: []exchange{
+			{Currency: "EUR", Rate: 0.5},
+			{Currency: "GBP", Rate: 0.4},
+		}}
+		got, err := rebaseToEUR(day)
+		if err != nil {
+			t.Fatalf("rebaseToEUR returned error: %v", err)
+		}
+		if got.Base != "EUR" {
+			t.Errorf("Base = %q, want EUR", got.Base)
+		}
+		want := map[string]float32{"GBP": 0.4 / 0.5, "USD": 1 / float32(0.5)}
+		if len(got.Rates) != len(want) {
+			t.Fatalf("Rates = %v, want %v", got.Rates, want)
+		}
+		for _, ex := range got.Rates {
+			if rate, ok := want[ex.Currency]; !ok || rate != ex.Rate {
+				t.Errorf("unexpected rate %+v, want one of %v", ex, want)
+			}
+		}
+	})
+
+	t.Run("missing EUR cross rate is an error", func(t *testing.T) {
+		day := DayRates{Date: "2026-07-27", Base: "USD", Rates: []exchange{{Currency: "GBP", Rate: 0.4}}}
+		if _, err := rebaseToEUR(day); err == nil {
+			t.Error("rebaseToEUR returned nil error for a day with no EUR cross rate")
+		}
+	})
+}
+
+func TestMergeDayRates(t *testing.T) {
+	primary := []DayRates{
+		{Date: "2026-07-27", Base: "EUR", Rates: []exchange{{Currency: "USD", Rate: 1.1}}},
+	}
+	secondary := []DayRates{
+		{Date: "2026-07-27", Base: "EUR", Rates: []exchange{
+			{Currency: "USD", Rate: 9.9}, // already present in primary, must not overwrite
+			{Currency: "GBP", Rate: 0.85},
+		}},
+		{Date: "2026-07-26", Base: "EUR", Rates: []exchange{{Currency: "GBP", Rate: 0.84}}},
+	}
+
+	merged := mergeDayRates(primary, secondary)
+
+	byDate := make(map[string][]exchange)
+	for _, d := range merged {
+		byDate[d.Date] = d.Rates
+	}
+
+	if rates, ok := byDate["2026-07-27"]; !ok {
+		t.Fatal("missing 2026-07-27 in merged output")
+	} else {
+		want := map[string]float32{"USD": 1.1, "GBP": 0.85}
+		if len(rates) != len(want) {
+			t.Fatalf("2026-07-27 rates = %v, want %v", rates, want)
+		}
+		for _, ex := range rates {
+			if rate, ok := want[ex.Currency]; !ok || rate != ex.Rate {
+				t.Errorf("2026-07-27: unexpected rate %+v, want one of %v", ex, want)
+			}
+		}
+	}
+
+	if rates, ok := byDate["2026-07-26"]; !ok || len(rates) != 1 || rates[0].Currency != "GBP" {
+		t.Errorf("2026-07-26 rates = %v, want a secondary-only GBP entry", rates)
+	}
+}
+
+// fakeProvider is a test double for Provider.
+type fakeProvider struct {
+	name string
+	days []DayRates
+	err  error
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) Fetch(ctx context.Context) ([]DayRates, error) {
+	return f.days, f.err
+}
+
+func sortedCurrencies(rates []exchange) []string {
+	var out []string
+	for _, ex := range rates {
+		out = append(out, ex.Currency)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestProviderChainFetch(t *testing.T) {
+	t.Run("primary success short-circuits the fallbacks", func(t *testing.T) {
+		fallbackCalled := false
+		chain := &ProviderChain{
+			primary: &fakeProvider{name: "primary", days: []DayRates{
+				{Date: "2026-07-27", Base: "EUR", Rates: []exchange{{Currency: "USD", Rate: 1.1}}},
+			}},
+			fallbacks: []Provider{&fakeProvider{name: "fallback", days: []DayRates{
+				{Date: "2026-07-27", Base: "EUR", Rates: []exchange{{Currency: "GBP", Rate: 0.85}}},
+			}}},
+			enableFallback: true,
+		}
+		// wrap the fallback to detect whether it was queried
+		chain.fallbacks[0] = &fetchRecorder{Provider: chain.fallbacks[0], called: &fallbackCalled}
+
+		days, err := chain.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch returned error: %v", err)
+		}
+		if fallbackCalled {
+			t.Error("fallback was queried despite a successful primary fetch")
+		}
+		if len(days) != 1 || sortedCurrencies(days[0].Rates)[0] != "USD" {
+			t.Errorf("days = %+v, want just the primary's USD rate", days)
+		}
+	})
+
+	t.Run("primary error falls back and merges", func(t *testing.T) {
+		chain := &ProviderChain{
+			primary: &fakeProvider{name: "primary", err: errors.New("boom")},
+			fallbacks: []Provider{&fakeProvider{name: "fallback", days: []DayRates{
+				{Date: "2026-07-27", Base: "EUR", Rates: []exchange{{Currency: "GBP", Rate: 0.85}}},
+			}}},
+			enableFallback: true,
+		}
+
+		days, err := chain.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch returned error: %v", err)
+		}
+		if len(days) != 1 || sortedCurrencies(days[0].Rates)[0] != "GBP" {
+			t.Errorf("days = %+v, want the fallback's GBP rate", days)
+		}
+	})
+
+	t.Run("primary empty result also falls back", func(t *testing.T) {
+		chain := &ProviderChain{
+			primary: &fakeProvider{name: "primary", days: nil},
+			fallbacks: []Provider{&fakeProvider{name: "fallback", days: []DayRates{
+				{Date: "2026-07-27", Base: "EUR", Rates: []exchange{{Currency: "GBP", Rate: 0.85}}},
+			}}},
+			enableFallback: true,
+		}
+
+		days, err := chain.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch returned error: %v", err)
+		}
+		if len(days) != 1 {
+			t.Errorf("days = %+v, want the fallback's single day", days)
+		}
+	})
+
+	t.Run("no provider returns anything is an error", func(t *testing.T) {
+		chain := &ProviderChain{
+			primary:        &fakeProvider{name: "primary", err: errors.New("boom")},
+			fallbacks:      []Provider{&fakeProvider{name: "fallback", err: errors.New("also boom")}},
+			enableFallback: true,
+		}
+
+		if _, err := chain.Fetch(context.Background()); err == nil {
+			t.Error("Fetch returned nil error when no provider returned any rates")
+		}
+	})
+
+	t.Run("fallback disabled surfaces the primary error without querying fallbacks", func(t *testing.T) {
+		fallbackCalled := false
+		chain := &ProviderChain{
+			primary:        &fakeProvider{name: "primary", err: errors.New("boom")},
+			fallbacks:      []Provider{&fetchRecorder{Provider: &fakeProvider{name: "fallback"}, called: &fallbackCalled}},
+			enableFallback: false,
+		}
+
+		if _, err := chain.Fetch(context.Background()); err == nil {
+			t.Error("Fetch returned nil error with fallback disabled and a failing primary")
+		}
+		if fallbackCalled {
+			t.Error("fallback was queried despite enableFallback=false")
+		}
+	})
+}
+
+// fetchRecorder wraps a Provider and records whether Fetch was called.
+type fetchRecorder struct {
+	Provider
+	called *bool
+}
+
+func (f *fetchRecorder) Fetch(ctx context.Context) ([]DayRates, error) {
+	*f.called = true
+	return f.Provider.Fetch(ctx)
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// imfProvider reads the IMF's monthly representative exchange rates via its
+// SDMX JSON data service. Rates are quoted against USD and only published
+// once per month, so callers should expect sparser dates than the daily
+// central bank sources.
+type imfProvider struct {
+	url string
+}
+
+func newIMFProvider() *imfProvider {
+	return &imfProvider{url: "http://dataservices.imf.org/REST/SDMX_JSON.svc/CompactData/IFS/M..ENDA_XDC_USD_RATE"}
+}
+
+func (p *imfProvider) Name() string { return "imf" }
+
+// imfAreaToCurrency maps the IMF SDMX @REF_AREA country/area code to the
+// ISO-4217 currency code it issues. @REF_AREA is an area code (e.g. "US",
+// "U2"), not a currency code, so it can't be used directly as Currency. "U2"
+// (the Euro area aggregate) is mapped to "EUR" deliberately: that's the entry
+// rebaseToEUR needs to pivot the rest of a day's rates. Area codes not listed
+// here are skipped rather than guessed.
+var imfAreaToCurrency = map[string]string{
+	"US": "USD",
+	"U2": "EUR",
+	"GB": "GBP",
+	"JP": "JPY",
+	"AU": "AUD",
+	"CA": "CAD",
+	"CH": "CHF",
+	"CN": "CNY",
+	"CZ": "CZK",
+	"DK": "DKK",
+	"HU": "HUF",
+	"NO": "NOK",
+	"NZ": "NZD",
+	"PL": "PLN",
+	"SE": "SEK",
+	"ZA": "ZAR",
+	"BR": "BRL",
+	"IN": "INR",
+	"ID": "IDR",
+	"IL": "ILS",
+	"KR": "KRW",
+	"MX": "MXN",
+	"MY": "MYR",
+	"PH": "PHP",
+	"SG": "SGD",
+	"TH": "THB",
+	"TR": "TRY",
+	"RU": "RUB",
+	"HK": "HKD",
+	"RO": "RON",
+	"BG": "BGN",
+	"HR": "HRK",
+}
+
+func (p *imfProvider) Fetch(ctx context.Context) ([]DayRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("imf: HTTP request returned %v", resp.Status)
+	}
+
+	return parseIMFResponse(resp.Body)
+}
+
+// parseIMFResponse decodes the IMF SDMX JSON payload into DayRates, split out
+// from Fetch so the @REF_AREA-to-currency mapping can be unit tested without
+// a network round trip.
+func parseIMFResponse(r io.Reader) ([]DayRates, error) {
+	var body struct {
+		CompactData struct {
+			DataSet struct {
+				Series []struct {
+					RefArea string `json:"@REF_AREA"`
+					Obs     []struct {
+						Period string `json:"@TIME_PERIOD"`
+						Value  string `json:"@OBS_VALUE"`
+					} `json:"Obs"`
+				} `json:"Series"`
+			} `json:"DataSet"`
+		} `json:"CompactData"`
+	}
+	if err := json.NewDecoder(r).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string][]exchange)
+	for _, series := range body.CompactData.DataSet.Series {
+		currency, ok := imfAreaToCurrency[series.RefArea]
+		if !ok {
+			continue
+		}
+		for _, obs := range series.Obs {
+			rate, err := strconv.ParseFloat(obs.Value, 32)
+			if err != nil {
+				continue
+			}
+			date := obs.Period + "-01"
+			byDate[date] = append(byDate[date], exchange{Currency: currency, Rate: float32(rate)})
+		}
+	}
+
+	days := make([]DayRates, 0, len(byDate))
+	for date, rates := range byDate {
+		days = append(days, DayRates{Date: date, Base: "USD", Rates: rates})
+	}
+	return days, nil
+}
+
+// bocProvider reads the Bank of Canada's Valet API, which reports how many
+// CAD one unit of foreign currency costs; we invert that to get the usual
+// "units of foreign currency per 1 CAD" convention.
+type bocProvider struct {
+	url string
+}
+
+func newBOCProvider() *bocProvider {
+	return &bocProvider{url: "https://www.bankofcanada.ca/valet/observations/group/FX_RATES_DAILY/json"}
+}
+
+func (p *bocProvider) Name() string { return "boc" }
+
+var bocSeriesRegexp = regexp.MustCompile(`^FX([A-Z]{3})CAD$`)
+
+func (p *bocProvider) Fetch(ctx context.Context) ([]DayRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("boc: HTTP request returned %v", resp.Status)
+	}
+
+	var body struct {
+		Observations []map[string]json.RawMessage `json:"observations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	days := make([]DayRates, 0, len(body.Observations))
+	for _, obs := range body.Observations {
+		var date string
+		if raw, ok := obs["d"]; ok {
+			json.Unmarshal(raw, &date)
+		}
+		if date == "" {
+			continue
+		}
+
+		var rates []exchange
+		for key, raw := range obs {
+			m := bocSeriesRegexp.FindStringSubmatch(key)
+			if m == nil {
+				continue
+			}
+			var series struct {
+				V string `json:"v"`
+			}
+			if err := json.Unmarshal(raw, &series); err != nil {
+				continue
+			}
+			cadPerUnit, err := strconv.ParseFloat(series.V, 32)
+			if err != nil || cadPerUnit == 0 {
+				continue
+			}
+			rates = append(rates, exchange{Currency: m[1], Rate: float32(1 / cadPerUnit)})
+		}
+		if len(rates) > 0 {
+			days = append(days, DayRates{Date: date, Base: "CAD", Rates: rates})
+		}
+	}
+	return days, nil
+}
+
+// rbaProvider reads the Reserve Bank of Australia's daily exchange rate CSV,
+// which lists one currency per column after a block of descriptive header
+// rows.
+type rbaProvider struct {
+	url string
+}
+
+func newRBAProvider() *rbaProvider {
+	return &rbaProvider{url: "https://www.rba.gov.au/statistics/tables/csv/f11.1-data.csv"}
+}
+
+func (p *rbaProvider) Name() string { return "rba" }
+
+func (p *rbaProvider) Fetch(ctx context.Context) ([]DayRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rba: HTTP request returned %v", resp.Status)
+	}
+
+	return parseRBACSV(resp.Body)
+}
+
+var rbaDateRegexp = regexp.MustCompile(`^\d{2}-[A-Za-z]{3}-\d{4}$`)
+
+// parseRBACSV parses the RBA's daily rate CSV, split out from Fetch so the
+// header/row parsing can be unit tested without a network round trip. A
+// trailing comma in the real export leaves an empty column header, so header
+// fields shorter than a currency code are skipped rather than sliced.
+func parseRBACSV(r io.Reader) ([]DayRates, error) {
+	scanner := bufio.NewScanner(r)
+	var currencies []string
+	var days []DayRates
+
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ",")
+		if len(fields) < 2 {
+			continue
+		}
+		if fields[0] == "Series ID" {
+			currencies = make([]string, len(fields))
+			for i, f := range fields[1:] {
+				code := strings.TrimSpace(strings.TrimPrefix(f, "FXR"))
+				if len(code) < 3 {
+					continue
+				}
+				currencies[i+1] = code[:3]
+			}
+			continue
+		}
+		if !rbaDateRegexp.MatchString(strings.TrimSpace(fields[0])) || currencies == nil {
+			continue
+		}
+
+		var rates []exchange
+		for i := 1; i < len(fields) && i < len(currencies); i++ {
+			if currencies[i] == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(fields[i]), 32)
+			if err != nil || value == 0 {
+				continue
+			}
+			rates = append(rates, exchange{Currency: currencies[i], Rate: float32(1 / value)})
+		}
+		if len(rates) > 0 {
+			days = append(days, DayRates{Date: fields[0], Base: "AUD", Rates: rates})
+		}
+	}
+	return days, scanner.Err()
+}
+
+// cnbProvider reads the Czech National Bank's yearly fixing file, a
+// pipe-delimited table of CZK-per-unit rates with one currency column per
+// header line.
+type cnbProvider struct {
+	url string
+}
+
+func newCNBProvider() *cnbProvider {
+	return &cnbProvider{url: "https://www.cnb.cz/en/financial_markets/foreign_exchange_market/exchange_rate_fixing/year.txt"}
+}
+
+func (p *cnbProvider) Name() string { return "cnb" }
+
+func (p *cnbProvider) Fetch(ctx context.Context) ([]DayRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cnb: HTTP request returned %v", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var currencies []string
+	var days []DayRates
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Split(line, "|")
+		if len(fields) < 2 {
+			continue
+		}
+		if currencies == nil {
+			currencies = fields[1:]
+			continue
+		}
+
+		date, err := cnbDateToISO(fields[0])
+		if err != nil {
+			continue
+		}
+
+		var rates []exchange
+		for i, code := range currencies {
+			if i+1 >= len(fields) {
+				break
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(fields[i+1]), 32)
+			if err != nil || value == 0 {
+				continue
+			}
+			rates = append(rates, exchange{Currency: strings.TrimSpace(code), Rate: float32(1 / value)})
+		}
+		if len(rates) > 0 {
+			days = append(days, DayRates{Date: date, Base: "CZK", Rates: rates})
+		}
+	}
+	return days, scanner.Err()
+}
+
+// cnbDateToISO converts the CNB's "02.01.2024" date format to "2024-01-02".
+func cnbDateToISO(d string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(d), ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("unrecognized CNB date %q", d)
+	}
+	return fmt.Sprintf("%s-%s-%s", parts[2], parts[1], parts[0]), nil
+}
+
+// nbpProvider reads the National Bank of Poland's "Table A" reference rate
+// API, which quotes the bulk of major currencies against PLN.
+type nbpProvider struct {
+	url string
+}
+
+func newNBPProvider() *nbpProvider {
+	return &nbpProvider{url: "http://api.nbp.pl/api/exchangerates/tables/A/last/255/?format=json"}
+}
+
+func (p *nbpProvider) Name() string { return "nbp" }
+
+func (p *nbpProvider) Fetch(ctx context.Context) ([]DayRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nbp: HTTP request returned %v", resp.Status)
+	}
+
+	var tables []struct {
+		EffectiveDate string `json:"effectiveDate"`
+		Rates         []struct {
+			Code string  `json:"code"`
+			Mid  float32 `json:"mid"`
+		} `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tables); err != nil {
+		return nil, err
+	}
+
+	days := make([]DayRates, 0, len(tables))
+	for _, t := range tables {
+		rates := make([]exchange, 0, len(t.Rates))
+		for _, r := range t.Rates {
+			rates = append(rates, exchange{Currency: r.Code, Rate: r.Mid})
+		}
+		days = append(days, DayRates{Date: t.EffectiveDate, Base: "PLN", Rates: rates})
+	}
+	return days, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseIMFResponse(t *testing.T) {
+	const body = `{
+		"CompactData": {
+			"DataSet": {
+				"Series": [
+					{"@REF_AREA": "U2", "Obs": [{"@TIME_PERIOD": "2026-06", "@OBS_VALUE": "1.1"}]},
+					{"@REF_AREA": "GB", "Obs": [{"@TIME_PERIOD": "2026-06", "@OBS_VALUE": "0.85"}]},
+					{"@REF_AREA": "ZZ", "Obs": [{"@TIME_PERIOD": "2026-06", "@OBS_VALUE": "99"}]}
+				]
+			}
+		}
+	}`
+
+	days, err := parseIMFResponse(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("parseIMFResponse returned error: %v", err)
+	}
+	if len(days) != 1 {
+		t.Fatalf("len(days) = %d, want 1 (one month)", len(days))
+	}
+
+	day := days[0]
+	if day.Date != "2026-06-01" {
+		t.Errorf("Date = %q, want 2026-06-01", day.Date)
+	}
+
+	want := map[string]float32{"EUR": 1.1, "GBP": 0.85}
+	if len(day.Rates) != len(want) {
+		t.Fatalf("Rates = %v, want currencies %v (an unmapped @REF_AREA like ZZ must be skipped)", day.Rates, want)
+	}
+	for _, ex := range day.Rates {
+		if rate, ok := want[ex.Currency]; !ok || rate != ex.Rate {
+			t.Errorf("unexpected rate %+v, want one of %v", ex, want)
+		}
+	}
+}
+
+func TestParseRBACSV(t *testing.T) {
+	// Real RBA exports have a trailing comma, leaving an empty header/data
+	// column; parsing must skip it instead of panicking on a short slice.
+	const csv = "Series ID,FXRUSD,FXRGBP,\n" +
+		"27-Jul-2026,1.5000,1.2000,\n"
+
+	days, err := parseRBACSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parseRBACSV returned error: %v", err)
+	}
+	if len(days) != 1 {
+		t.Fatalf("len(days) = %d, want 1", len(days))
+	}
+
+	day := days[0]
+	if day.Date != "27-Jul-2026" {
+		t.Errorf("Date = %q, want 27-Jul-2026", day.Date)
+	}
+
+	wantRBA := map[string]float32{"USD": 1 / float32(1.5), "GBP": 1 / float32(1.2)}
+	if len(day.Rates) != len(wantRBA) {
+		t.Fatalf("Rates = %v, want %v", day.Rates, wantRBA)
+	}
+	for _, ex := range day.Rates {
+		if rate, ok := wantRBA[ex.Currency]; !ok || rate != ex.Rate {
+			t.Errorf("unexpected rate %+v, want one of %v", ex, wantRBA)
+		}
+	}
+}
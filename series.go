@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// accept strings like /series/USD
+var seriesRoutingRegexp = regexp.MustCompile(`^/series/([A-Za-z]{3})/?$`)
+
+const (
+	stepDaily   = "daily"
+	stepWeekly  = "weekly"
+	stepMonthly = "monthly"
+)
+
+type seriesPoint struct {
+	Date string  `json:"date"`
+	Rate float32 `json:"rate"`
+}
+
+type seriesResponse struct {
+	Currency  string        `json:"currency"`
+	Points    []seriesPoint `json:"points"`
+	Min       float32       `json:"min"`
+	Max       float32       `json:"max"`
+	Avg       float32       `json:"avg"`
+	PctChange float32       `json:"pctChange"`
+}
+
+// sortedDates returns every date with cached rates, ascending.
+func sortedDates() []string {
+	exchangeRatesMu.RLock()
+	defer exchangeRatesMu.RUnlock()
+
+	dates := make([]string, len(availableDates))
+	copy(dates, availableDates)
+	return dates
+}
+
+func rateForCurrency(exs []exchange, currency string) (float32, bool) {
+	if currency == "EUR" {
+		return 1, true
+	}
+	for _, ex := range exs {
+		if ex.Currency == currency {
+			return ex.Rate, true
+		}
+	}
+	return 0, false
+}
+
+// buildSeries walks the cached dates between from and to (inclusive,
+// either may be empty for an open end), re-basing through base when it
+// isn't empty or "EUR". min/max/avg/pctChange are computed over every day
+// in range; Points keeps only one sample per step so a weekly/monthly chart
+// doesn't silently miss an extreme that fell on a day between samples.
+func buildSeries(currency, from, to, step, base string) seriesResponse {
+	resp := seriesResponse{Currency: currency}
+	dates := sortedDates()
+
+	exchangeRatesMu.RLock()
+	defer exchangeRatesMu.RUnlock()
+
+	var lastBucket string
+	var count int
+	var sum, first, last float32
+
+	for _, date := range dates {
+		if from != "" && date < from {
+			continue
+		}
+		if to != "" && date > to {
+			continue
+		}
+
+		exs := exchangeRates[date]
+		var rate float32
+		var ok bool
+		if base == "" || base == "EUR" {
+			rate, ok = rateForCurrency(exs, currency)
+		} else {
+			normalized, err := Normalize(exs, base)
+			if err != nil {
+				continue
+			}
+			rate, ok = normalized[currency]
+		}
+		if !ok {
+			continue
+		}
+
+		if count == 0 {
+			resp.Min, resp.Max = rate, rate
+			first = rate
+		}
+		if rate < resp.Min {
+			resp.Min = rate
+		}
+		if rate > resp.Max {
+			resp.Max = rate
+		}
+		sum += rate
+		last = rate
+		count++
+
+		if bucket, ok := seriesBucket(date, step); ok && bucket != lastBucket {
+			lastBucket = bucket
+			resp.Points = append(resp.Points, seriesPoint{Date: date, Rate: rate})
+		}
+	}
+
+	if count == 0 {
+		return resp
+	}
+
+	resp.Avg = sum / float32(count)
+	resp.PctChange = (last - first) / first * 100
+
+	return resp
+}
+
+// seriesBucket maps date to the key that should be unique per step, so
+// weekly/monthly series keep only the first date seen in each bucket.
+func seriesBucket(date, step string) (string, bool) {
+	switch step {
+	case stepDaily:
+		return date, true
+	case stepWeekly:
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return "", false
+		}
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), true
+	case stepMonthly:
+		if len(date) < 7 {
+			return "", false
+		}
+		return date[:7], true
+	default:
+		return "", false
+	}
+}
+
+// refreshRecentRates fetches the lighter 90-day feed and merges it into
+// exchangeRates when the requested range falls inside that window, so a
+// /series request for a recent range doesn't have to wait on the next
+// hourly full-history refresh. Older or open-ended ranges keep relying on
+// that periodic refresh instead of fetching the full history again here.
+func refreshRecentRates(from string) {
+	if from == "" {
+		return
+	}
+	t, err := time.Parse("2006-01-02", from)
+	if err != nil || time.Since(t) > 90*24*time.Hour {
+		return
+	}
+
+	days, err := newECBProvider(eurHist90dURL).Fetch(context.Background())
+	if err != nil {
+		return
+	}
+
+	exchangeRatesMu.Lock()
+	for _, d := range rebaseAndFilterDays(days) {
+		if _, ok := exchangeRates[d.Date]; !ok {
+			exchangeRates[d.Date] = d.Rates
+		}
+	}
+	refreshAvailableDates()
+	exchangeRatesMu.Unlock()
+}
+
+func seriesHandler(w http.ResponseWriter, req *http.Request) {
+	m := seriesRoutingRegexp.FindStringSubmatch(req.URL.Path)
+	if m == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	currency := m[1]
+
+	q := req.URL.Query()
+	step := q.Get("step")
+	if step == "" {
+		step = stepDaily
+	}
+	if step != stepDaily && step != stepWeekly && step != stepMonthly {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	refreshRecentRates(q.Get("from"))
+
+	resp := buildSeries(currency, q.Get("from"), q.Get("to"), step, q.Get("base"))
+	if len(resp.Points) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
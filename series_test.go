@@ -0,0 +1,88 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeriesBucket(t *testing.T) {
+	tests := []struct {
+		date, step string
+		want       string
+		wantOK     bool
+	}{
+		{"2026-07-20", stepDaily, "2026-07-20", true},
+		{"2026-07-20", stepWeekly, "2026-W30", true},
+		{"2026-07-20", stepMonthly, "2026-07", true},
+		{"2026-07-20", "yearly", "", false},
+		{"not-a-date", stepWeekly, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := seriesBucket(tt.date, tt.step)
+		if ok != tt.wantOK {
+			t.Errorf("seriesBucket(%q, %q) ok = %v, want %v", tt.date, tt.step, ok, tt.wantOK)
+			continue
+		}
+		if ok && got != tt.want {
+			t.Errorf("seriesBucket(%q, %q) = %q, want %q", tt.date, tt.step, got, tt.want)
+		}
+	}
+}
+
+func seedExchangeRates(t *testing.T, rates map[string][]exchange) {
+	t.Helper()
+	exchangeRatesMu.Lock()
+	defer exchangeRatesMu.Unlock()
+	exchangeRates = rates
+	refreshAvailableDates()
+}
+
+func TestBuildSeries(t *testing.T) {
+	seedExchangeRates(t, map[string][]exchange{
+		"2026-07-20": {{Currency: "USD", Rate: 1.10}},
+		"2026-07-21": {{Currency: "USD", Rate: 1.50}}, // mid-week spike
+		"2026-07-22": {{Currency: "USD", Rate: 1.09}},
+		"2026-07-27": {{Currency: "USD", Rate: 1.11}},
+	})
+
+	t.Run("daily keeps one point per date in range", func(t *testing.T) {
+		resp := buildSeries("USD", "2026-07-20", "2026-07-22", stepDaily, "")
+		var dates []string
+		for _, p := range resp.Points {
+			dates = append(dates, p.Date)
+		}
+		want := []string{"2026-07-20", "2026-07-21", "2026-07-22"}
+		if !reflect.DeepEqual(dates, want) {
+			t.Errorf("dates = %v, want %v", dates, want)
+		}
+	})
+
+	t.Run("weekly decimates points but keeps full-range min/max", func(t *testing.T) {
+		resp := buildSeries("USD", "2026-07-20", "2026-07-27", stepWeekly, "")
+		if len(resp.Points) != 2 {
+			t.Fatalf("len(Points) = %d, want 2", len(resp.Points))
+		}
+		if resp.Min != 1.09 {
+			t.Errorf("Min = %v, want 1.09 (from 2026-07-22, which isn't a sampled point)", resp.Min)
+		}
+		if resp.Max != 1.50 {
+			t.Errorf("Max = %v, want 1.50 (from 2026-07-21, which isn't a sampled point)", resp.Max)
+		}
+	})
+
+	t.Run("pctChange compares the first and last day in range, not the first/last sampled point", func(t *testing.T) {
+		resp := buildSeries("USD", "2026-07-20", "2026-07-27", stepWeekly, "")
+		want := (float32(1.11) - float32(1.10)) / float32(1.10) * 100
+		if resp.PctChange != want {
+			t.Errorf("PctChange = %v, want %v", resp.PctChange, want)
+		}
+	})
+
+	t.Run("missing currency on every date yields no points", func(t *testing.T) {
+		resp := buildSeries("ZZZ", "2026-07-20", "2026-07-27", stepDaily, "")
+		if len(resp.Points) != 0 {
+			t.Errorf("Points = %v, want none", resp.Points)
+		}
+	})
+}